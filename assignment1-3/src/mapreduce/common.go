@@ -0,0 +1,23 @@
+package mapreduce
+
+import "fmt"
+
+// Debug turns on verbose logging from debug(). 0 (the default) is quiet.
+const Debug = 0
+
+// debug prints only when Debug is non-zero, so the scheduler and workers
+// can narrate what they're doing without spamming normal runs.
+func debug(format string, a ...interface{}) (n int, err error) {
+	if Debug > 0 {
+		n, err = fmt.Printf(format, a...)
+	}
+	return
+}
+
+// jobPhase distinguishes the two stages of a MapReduce job.
+type jobPhase string
+
+const (
+	mapPhase    jobPhase = "Map"
+	reducePhase jobPhase = "Reduce"
+)
@@ -0,0 +1,44 @@
+package mapreduce
+
+import (
+	"fmt"
+	"net/rpc"
+)
+
+// DoTaskArgs is the argument to the Worker.DoTask RPC, telling a worker
+// which task of which phase to run and how many files of the other phase
+// it needs to know about.
+type DoTaskArgs struct {
+	JobName       string
+	File          string
+	Phase         jobPhase
+	TaskNumber    int
+	NumOtherPhase int
+}
+
+// RegisterArgs is the argument to the Master.Register RPC, by which a
+// worker announces it's ready to receive tasks.
+type RegisterArgs struct {
+	Worker string
+}
+
+// PingArgs and PingReply are the RPC pair the master uses to probe a
+// worker's liveness, independent of whether it's currently running a task.
+type PingArgs struct{}
+type PingReply struct{}
+
+// call sends an RPC to the rpcname handler on server srv, waits for the
+// response, and returns whether it was received without error.
+func call(srv string, rpcname string, args interface{}, reply interface{}) bool {
+	c, err := rpc.Dial("unix", srv)
+	if err != nil {
+		return false
+	}
+	defer c.Close()
+
+	if err := c.Call(rpcname, args, reply); err != nil {
+		fmt.Println(err)
+		return false
+	}
+	return true
+}
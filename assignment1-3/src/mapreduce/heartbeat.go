@@ -0,0 +1,190 @@
+package mapreduce
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// heartbeatInterval is how often the master pings a registered worker to
+// confirm it's still alive. staleAfter is how many missed intervals a
+// worker can go silent for before dispatch stops trusting it with new
+// tasks, even though mr.registerChannel still offers it. Both are vars,
+// not consts, so tests can shrink them instead of waiting out the real
+// intervals.
+var (
+	heartbeatInterval = 2 * time.Second
+	staleAfter        = 3 * heartbeatInterval
+)
+
+// WorkerStatus is a point-in-time, JSON-friendly view of one registered
+// worker, as returned by the /status endpoint.
+type WorkerStatus struct {
+	Host           string
+	Pid            int
+	StartedAt      time.Time
+	LastHeartbeat  time.Time
+	CurrentTask    int // -1 when idle
+	TasksCompleted int
+	Status         string // "idle", "busy", or "dead"
+}
+
+// workerInfo is the registry's mutable record for one worker; WorkerStatus
+// is embedded so field access reads the same as the exported view, with
+// mu guarding updates from the heartbeat ticker and the scheduler both.
+type workerInfo struct {
+	mu sync.Mutex
+	WorkerStatus
+}
+
+// workerRegistry tracks every worker the master has ever seen, keyed by
+// its RPC address, so dispatch can skip ones that have gone quiet and
+// operators can inspect the table via /status.
+type workerRegistry struct {
+	mu      sync.Mutex
+	workers map[string]*workerInfo
+}
+
+func newWorkerRegistry() *workerRegistry {
+	return &workerRegistry{workers: make(map[string]*workerInfo)}
+}
+
+// Register records host's first sighting (or refreshes its heartbeat if
+// it's already known, e.g. it re-registered after a restart).
+func (r *workerRegistry) Register(host string) {
+	r.mu.Lock()
+	info, ok := r.workers[host]
+	if !ok {
+		info = &workerInfo{WorkerStatus: WorkerStatus{Host: host, StartedAt: time.Now(), CurrentTask: -1, Status: "idle"}}
+		r.workers[host] = info
+	}
+	r.mu.Unlock()
+
+	info.mu.Lock()
+	info.LastHeartbeat = time.Now()
+	if info.Status == "dead" {
+		info.Status = "idle"
+	}
+	info.mu.Unlock()
+}
+
+// IsAlive reports whether host's last heartbeat is recent enough to still
+// trust it with a new task. An unregistered host is assumed alive, since
+// it's about to be registered for the first time.
+func (r *workerRegistry) IsAlive(host string) bool {
+	r.mu.Lock()
+	info, ok := r.workers[host]
+	r.mu.Unlock()
+	if !ok {
+		return true
+	}
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	return time.Since(info.LastHeartbeat) <= staleAfter
+}
+
+// MarkBusy records that host has started running task.
+func (r *workerRegistry) MarkBusy(host string, task int) {
+	r.mu.Lock()
+	info, ok := r.workers[host]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	info.mu.Lock()
+	info.CurrentTask = task
+	info.Status = "busy"
+	info.mu.Unlock()
+}
+
+// MarkDone records that host finished its current task successfully and
+// goes back to idle.
+func (r *workerRegistry) MarkDone(host string) {
+	r.mu.Lock()
+	info, ok := r.workers[host]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	info.mu.Lock()
+	info.CurrentTask = -1
+	info.TasksCompleted++
+	info.Status = "idle"
+	info.mu.Unlock()
+}
+
+// StartHeartbeats pings every registered worker every heartbeatInterval
+// until stop is closed, refreshing LastHeartbeat on a successful reply
+// and marking the worker dead otherwise.
+func (r *workerRegistry) StartHeartbeats(stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.pingAll()
+			}
+		}
+	}()
+}
+
+func (r *workerRegistry) pingAll() {
+	r.mu.Lock()
+	hosts := make([]string, 0, len(r.workers))
+	for host := range r.workers {
+		hosts = append(hosts, host)
+	}
+	r.mu.Unlock()
+
+	for _, host := range hosts {
+		host := host
+		go func() {
+			r.mu.Lock()
+			info := r.workers[host]
+			r.mu.Unlock()
+
+			if call(host, "Worker.Ping", PingArgs{}, new(PingReply)) {
+				info.mu.Lock()
+				info.LastHeartbeat = time.Now()
+				if info.Status != "busy" {
+					info.Status = "idle"
+				}
+				info.mu.Unlock()
+			} else {
+				info.mu.Lock()
+				info.Status = "dead"
+				info.mu.Unlock()
+			}
+		}()
+	}
+}
+
+// Snapshot returns a point-in-time copy of every tracked worker, for the
+// /status endpoint.
+func (r *workerRegistry) Snapshot() []WorkerStatus {
+	r.mu.Lock()
+	infos := make([]*workerInfo, 0, len(r.workers))
+	for _, info := range r.workers {
+		infos = append(infos, info)
+	}
+	r.mu.Unlock()
+
+	out := make([]WorkerStatus, len(infos))
+	for i, info := range infos {
+		info.mu.Lock()
+		out[i] = info.WorkerStatus
+		info.mu.Unlock()
+	}
+	return out
+}
+
+// ServeStatus answers /status with the live worker table as JSON, so
+// operators can see stragglers without digging through debug logs.
+func (mr *Master) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mr.heartbeats.Snapshot())
+}
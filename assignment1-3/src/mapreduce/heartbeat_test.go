@@ -0,0 +1,100 @@
+package mapreduce
+
+import (
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+)
+
+// killableWorker behaves like a normal worker until killed, at which
+// point its listener is torn down so every subsequent RPC fails to even
+// connect — the same symptom the master sees when a worker process dies
+// mid-task.
+type killableWorker struct {
+	l net.Listener
+}
+
+func (w *killableWorker) DoTask(args *DoTaskArgs, _ *struct{}) error { return nil }
+func (w *killableWorker) Ping(args *PingArgs, reply *PingReply) error { return nil }
+
+func startKillableWorker(t *testing.T) (addr string, kill func()) {
+	t.Helper()
+
+	wk := &killableWorker{}
+	rpcs := rpc.NewServer()
+	if err := rpcs.RegisterName("Worker", wk); err != nil {
+		t.Fatalf("RegisterName: %v", err)
+	}
+
+	l, err := net.Listen("unix", t.TempDir()+"/worker.sock")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	wk.l = l
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go rpcs.ServeConn(conn)
+		}
+	}()
+
+	return l.Addr().String(), func() { l.Close() }
+}
+
+// TestHeartbeatsDetectWorkerKilledMidTask simulates a worker that's
+// killed in the middle of a task: once it stops answering pings, the
+// registry must mark it dead so dispatch (worker_pool.go's run) skips it
+// instead of handing it more work.
+func TestHeartbeatsDetectWorkerKilledMidTask(t *testing.T) {
+	origInterval, origStale := heartbeatInterval, staleAfter
+	heartbeatInterval = 10 * time.Millisecond
+	staleAfter = 3 * heartbeatInterval
+	defer func() { heartbeatInterval, staleAfter = origInterval, origStale }()
+
+	addr, kill := startKillableWorker(t)
+
+	r := newWorkerRegistry()
+	r.Register(addr)
+	if !r.IsAlive(addr) {
+		t.Fatal("freshly registered worker reported dead")
+	}
+	r.MarkBusy(addr, 0)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	r.StartHeartbeats(stop)
+
+	// Kill the worker mid-task: its listener goes away, so every ping
+	// from here on fails to dial, just like a crashed process would.
+	kill()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for r.IsAlive(addr) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if r.IsAlive(addr) {
+		t.Fatal("registry still considers the killed worker alive")
+	}
+
+	statuses := r.Snapshot()
+	if len(statuses) != 1 || statuses[0].Status != "dead" {
+		t.Fatalf("Snapshot = %+v, want exactly one worker with Status=dead", statuses)
+	}
+}
+
+// TestWorkerPingRespondsWhileAlive is a narrow check that Worker.Ping
+// itself is wired up: call() against a real *Worker must succeed, since
+// the heartbeat registry's liveness tracking depends entirely on it.
+func TestWorkerPingRespondsWhileAlive(t *testing.T) {
+	wk := &Worker{name: "test-worker"}
+	addr := startTestWorker(t, wk)
+
+	if !call(addr, "Worker.Ping", PingArgs{}, new(PingReply)) {
+		t.Fatal("Worker.Ping failed against a live worker")
+	}
+}
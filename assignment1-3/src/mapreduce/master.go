@@ -0,0 +1,103 @@
+package mapreduce
+
+import (
+	"net"
+	"net/http"
+	"net/rpc"
+	"sync"
+)
+
+// Master holds all the state the MapReduce master needs: the job
+// description schedule uses to dispatch tasks, the channel workers
+// register themselves on, and the heartbeat table dispatch consults so
+// it doesn't keep handing tasks to a worker that's gone quiet.
+type Master struct {
+	sync.Mutex
+
+	address         string
+	registerChannel chan string
+	doneChannel     chan bool
+
+	jobName string
+	files   []string
+	nReduce int
+
+	heartbeats *workerRegistry
+
+	l        net.Listener
+	shutdown chan struct{}
+}
+
+// newMaster allocates a Master listening for worker registrations and RPCs
+// on address, with its heartbeat registry ready to track whichever
+// workers register.
+func newMaster(address string, jobName string, files []string, nReduce int) *Master {
+	mr := &Master{
+		address:         address,
+		registerChannel: make(chan string),
+		doneChannel:     make(chan bool),
+		jobName:         jobName,
+		files:           files,
+		nReduce:         nReduce,
+		heartbeats:      newWorkerRegistry(),
+		shutdown:        make(chan struct{}),
+	}
+	mr.heartbeats.StartHeartbeats(mr.shutdown)
+	if err := mr.startStatusServer("127.0.0.1:0"); err != nil {
+		debug("newMaster: failed to start /status server: %v\n", err)
+	}
+	return mr
+}
+
+// Register is the RPC a worker calls to announce it's ready for tasks. It
+// records the worker in the heartbeat registry and hands it to whichever
+// dispatch loop is waiting on registerChannel.
+func (mr *Master) Register(args *RegisterArgs, _ *struct{}) error {
+	mr.Lock()
+	defer mr.Unlock()
+	debug("Register: worker %s\n", args.Worker)
+	mr.heartbeats.Register(args.Worker)
+	go func() { mr.registerChannel <- args.Worker }()
+	return nil
+}
+
+// startRPCServer starts serving Master's RPCs (including Register) over a
+// Unix-domain socket at mr.address.
+func (mr *Master) startRPCServer() error {
+	rpcs := rpc.NewServer()
+	if err := rpcs.Register(mr); err != nil {
+		return err
+	}
+
+	l, err := net.Listen("unix", mr.address)
+	if err != nil {
+		return err
+	}
+	mr.l = l
+
+	go func() {
+		for {
+			conn, err := mr.l.Accept()
+			if err != nil {
+				return
+			}
+			go rpcs.ServeConn(conn)
+		}
+	}()
+	return nil
+}
+
+// startStatusServer serves the /status endpoint (the live worker table,
+// as JSON) at addr, so operators can observe stragglers without digging
+// through debug logs.
+func (mr *Master) startStatusServer(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", mr.ServeStatus)
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go http.Serve(l, mux)
+	return nil
+}
@@ -1,34 +1,13 @@
 package mapreduce
 
-import (
-	"sync"
-)
+import "time"
 
-func (mr *Master) dispatchWorker(task int, nOtherPhase int, phase jobPhase, wg *sync.WaitGroup) {
-	for {
-		debug("Starting worker: %v\n", task)
-
-		worker := <-mr.registerChannel
-
-		var args DoTaskArgs
-		args.JobName = mr.jobName
-		args.File = mr.files[task]
-		args.Phase = phase
-		args.TaskNumber = task
-		args.NumOtherPhase = nOtherPhase
-
-		ok := call(worker, "Worker.DoTask", args, new(struct{}))
-		if ok {
-			wg.Done()
-			mr.registerChannel <- worker
-			break
-		} else {
-			// if the master's RPC to the worker fails,
-			// the master should re-assign the task given to the failed worker to another worker.
-			debug("Worker failed with task: %v\n", task)
-		}
-	}
-}
+// taskTimeout bounds how long the WorkerPool waits for a single
+// Worker.DoTask RPC before assuming the worker is stuck and
+// re-dispatching the task to another worker, mirroring the 10-second
+// timeout used in the 6.824 MapReduce lab. It's a var, not a const, so
+// tests can shorten it instead of waiting out the real timeout.
+var taskTimeout = 10 * time.Second
 
 // schedule starts and waits for all tasks in the given phase (Map or Reduce).
 func (mr *Master) schedule(phase jobPhase) {
@@ -45,18 +24,24 @@ func (mr *Master) schedule(phase jobPhase) {
 
 	debug("Schedule: %v %v tasks (%d I/Os)\n", ntasks, phase, nios)
 
-	// All ntasks tasks have to be scheduled on workers, and only once all of
-	// them have been completed successfully should the function return.
-	// Remember that workers may fail, and that any given worker may finish
-	// multiple tasks.
-	//
+	tasks := make([]Task, ntasks)
+	for i := range tasks {
+		tasks[i] = Task{TaskNumber: i, NOtherPhase: nios, Phase: phase}
+	}
 
-	var workerGroup sync.WaitGroup
-	for task := 0; task < ntasks; task++ {
-		workerGroup.Add(1)
-		go mr.dispatchWorker(task, nios, phase, &workerGroup)
+	// The all-tasks-at-once fan-out this phase used to do directly with a
+	// sync.WaitGroup is now just the FanOutStrategy running on a
+	// WorkerPool; schedule only has to drain results until the batch is
+	// done, which keeps it reusable (and independently testable) for
+	// future phases that want a different Strategy.
+	pool := NewWorkerPool(mr)
+	batch := pool.SubmitBatch(NewFanOutStrategy(tasks), ntasks)
+	for {
+		if done, total, finished := pool.BatchStatus(batch); finished && done >= total {
+			break
+		}
+		<-pool.Results()
 	}
-	workerGroup.Wait()
 
 	//schedule only needs to tell the workers the name of the original
 	//input file (mr.files[task]) and the task task; each worker knows
@@ -0,0 +1,109 @@
+package mapreduce
+
+import (
+	"net"
+	"net/rpc"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hangingWorker simulates a worker that hangs past taskTimeout on the
+// first DoTask it receives (so schedule has to time it out and
+// re-dispatch) but answers immediately on every later call, so the
+// re-dispatched attempt succeeds. hungDone is closed once that first call
+// returns, so the test can wait for it instead of racing the package-level
+// taskTimeout var against this goroutine's read of it.
+type hangingWorker struct {
+	calls    int32
+	hungDone chan struct{}
+}
+
+func newHangingWorker() *hangingWorker {
+	return &hangingWorker{hungDone: make(chan struct{})}
+}
+
+func (w *hangingWorker) DoTask(args *DoTaskArgs, _ *struct{}) error {
+	if atomic.AddInt32(&w.calls, 1) == 1 {
+		time.Sleep(taskTimeout * 3)
+		close(w.hungDone)
+	}
+	return nil
+}
+
+// startTestWorker serves receiver as the RPC service "Worker" over a
+// fresh Unix socket under t.TempDir(), and returns its address.
+func startTestWorker(t *testing.T, receiver interface{}) string {
+	t.Helper()
+
+	rpcs := rpc.NewServer()
+	if err := rpcs.RegisterName("Worker", receiver); err != nil {
+		t.Fatalf("RegisterName: %v", err)
+	}
+
+	l, err := net.Listen("unix", t.TempDir()+"/worker.sock")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go rpcs.ServeConn(conn)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+// TestScheduleSurvivesHangingWorker simulates a worker that hangs on its
+// first task: schedule must time that attempt out, re-dispatch the task,
+// and still finish the phase instead of blocking forever.
+func TestScheduleSurvivesHangingWorker(t *testing.T) {
+	origTimeout := taskTimeout
+	taskTimeout = 20 * time.Millisecond
+
+	worker := newHangingWorker()
+	mr := newMaster("", "test-job", []string{"f0", "f1"}, 1)
+	addr := startTestWorker(t, worker)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case mr.registerChannel <- addr:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		mr.schedule(mapPhase)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		taskTimeout = origTimeout
+		t.Fatal("schedule did not finish after a worker hung on its first task")
+	}
+
+	// Wait for the hung call's handler to actually return before restoring
+	// taskTimeout: it reads the package var when the call starts, and
+	// nothing else orders that read before the restore below, so without
+	// synchronizing on it here the two would race.
+	select {
+	case <-worker.hungDone:
+	case <-time.After(time.Second):
+		t.Error("hung call's handler never returned")
+	}
+	taskTimeout = origTimeout
+}
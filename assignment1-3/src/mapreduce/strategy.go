@@ -0,0 +1,137 @@
+package mapreduce
+
+// Task describes a single unit of work that can be handed to a worker.
+type Task struct {
+	TaskNumber  int
+	NOtherPhase int
+	Phase       jobPhase
+}
+
+// TaskAssignment pairs a Task with the worker it should run on.
+type TaskAssignment struct {
+	Worker string
+	Task   Task
+}
+
+// TaskResult reports the outcome of running a task on a worker.
+type TaskResult struct {
+	Worker string
+	Task   Task
+	OK     bool
+}
+
+// Strategy decides which free workers get which tasks next, and reacts
+// to results as they come back. A Strategy is only ever driven by a
+// single WorkerPool at a time, so implementations don't need to be
+// safe for concurrent use.
+type Strategy interface {
+	// NextActions returns the assignments to make given the workers that
+	// are currently free. It may return fewer assignments than there are
+	// free workers (or none at all) if there's no work ready for them yet.
+	NextActions(freeWorkers []string) []TaskAssignment
+
+	// Process updates the strategy's bookkeeping with the outcome of
+	// completed assignments, e.g. marking a task done or re-queueing a
+	// failed one.
+	Process(results []TaskResult) error
+
+	// Done reports whether every task the strategy was given has
+	// completed successfully.
+	Done() bool
+}
+
+// FanOutStrategy assigns every task to the first free worker available,
+// re-queuing failed tasks for whichever worker is free next (which may be
+// the same worker). It's the strategy behind
+// the original all-tasks-at-once fan-out that schedule used to do with a
+// sync.WaitGroup directly.
+type FanOutStrategy struct {
+	pending   []Task
+	remaining map[int]bool // task numbers not yet completed
+}
+
+// NewFanOutStrategy returns a Strategy that dispatches tasks in order and
+// re-queues any that fail, until all of them succeed.
+func NewFanOutStrategy(tasks []Task) *FanOutStrategy {
+	remaining := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		remaining[t.TaskNumber] = true
+	}
+	return &FanOutStrategy{
+		pending:   append([]Task(nil), tasks...),
+		remaining: remaining,
+	}
+}
+
+func (s *FanOutStrategy) NextActions(freeWorkers []string) []TaskAssignment {
+	var actions []TaskAssignment
+	for _, worker := range freeWorkers {
+		if len(s.pending) == 0 {
+			break
+		}
+		task := s.pending[0]
+		s.pending = s.pending[1:]
+		actions = append(actions, TaskAssignment{Worker: worker, Task: task})
+	}
+	return actions
+}
+
+func (s *FanOutStrategy) Process(results []TaskResult) error {
+	for _, r := range results {
+		if r.OK {
+			delete(s.remaining, r.Task.TaskNumber)
+		} else {
+			s.pending = append(s.pending, r.Task)
+		}
+	}
+	return nil
+}
+
+func (s *FanOutStrategy) Done() bool {
+	return len(s.remaining) == 0
+}
+
+// LocalityStrategy is a FanOutStrategy that, given which worker produced
+// each map task's output, prefers to hand a free worker the pending task
+// whose input it already has locally (e.g. a reduce task reading the
+// intermediate file a map task it ran just wrote), to avoid an extra
+// network copy of that intermediate data.
+type LocalityStrategy struct {
+	FanOutStrategy
+	producedBy map[int]string // task number -> worker that produced the input it will read
+}
+
+// NewLocalityStrategy returns a Strategy like FanOutStrategy but that
+// prefers locality: producedBy maps a task number to the worker that
+// produced the input it will read.
+func NewLocalityStrategy(tasks []Task, producedBy map[int]string) *LocalityStrategy {
+	return &LocalityStrategy{
+		FanOutStrategy: *NewFanOutStrategy(tasks),
+		producedBy:     producedBy,
+	}
+}
+
+func (s *LocalityStrategy) NextActions(freeWorkers []string) []TaskAssignment {
+	var actions []TaskAssignment
+	for _, worker := range freeWorkers {
+		if len(s.pending) == 0 {
+			break
+		}
+		i := s.localTaskIndex(worker)
+		task := s.pending[i]
+		s.pending = append(s.pending[:i], s.pending[i+1:]...)
+		actions = append(actions, TaskAssignment{Worker: worker, Task: task})
+	}
+	return actions
+}
+
+// localTaskIndex returns the index of the first pending task whose input
+// worker already produced, or 0 (the oldest pending task) if none match.
+func (s *LocalityStrategy) localTaskIndex(worker string) int {
+	for i, task := range s.pending {
+		if s.producedBy[task.TaskNumber] == worker {
+			return i
+		}
+	}
+	return 0
+}
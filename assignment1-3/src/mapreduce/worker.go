@@ -0,0 +1,25 @@
+package mapreduce
+
+import "sync"
+
+// Worker holds the state for a single worker process: its RPC address.
+type Worker struct {
+	sync.Mutex
+	name string
+}
+
+// DoTask is called remotely by the master to run one map or reduce task.
+// Task execution itself (reading input, running the user's Map/Reduce
+// function, writing output) belongs to a later assignment and isn't
+// implemented here.
+func (wk *Worker) DoTask(args *DoTaskArgs, _ *struct{}) error {
+	debug("%s: given %v task #%d on file %s\n", wk.name, args.Phase, args.TaskNumber, args.File)
+	return nil
+}
+
+// Ping answers the master's liveness probe. It always succeeds as long as
+// the worker's RPC server is up to receive the call at all, which is the
+// only thing the heartbeat registry needs to know.
+func (wk *Worker) Ping(args *PingArgs, reply *PingReply) error {
+	return nil
+}
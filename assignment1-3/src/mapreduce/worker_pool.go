@@ -0,0 +1,203 @@
+package mapreduce
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchID identifies a batch of tasks submitted to a WorkerPool.
+type BatchID int
+
+// WorkerPool dispatches tasks to registered workers under a Strategy.
+// SubmitBatch returns immediately; the caller consumes completions from
+// Results() as they arrive, so a later phase (e.g. reduce) can start
+// overlapping with a batch that hasn't fully finished yet (e.g. a
+// straggling map task).
+type WorkerPool struct {
+	mr      *Master
+	results chan TaskResult
+
+	mu      sync.Mutex
+	nextID  BatchID
+	batches map[BatchID]*batchState
+}
+
+type batchState struct {
+	strategy Strategy
+	total    int
+	done     int32
+}
+
+// NewWorkerPool returns a WorkerPool that pulls free workers from mr's
+// registerChannel.
+func NewWorkerPool(mr *Master) *WorkerPool {
+	return &WorkerPool{
+		mr:      mr,
+		results: make(chan TaskResult, 100),
+		batches: make(map[BatchID]*batchState),
+	}
+}
+
+// SubmitBatch starts dispatching tasks under strategy in the background
+// and returns immediately with an id BatchStatus can be queried with.
+func (p *WorkerPool) SubmitBatch(strategy Strategy, total int) BatchID {
+	p.mu.Lock()
+	id := p.nextID
+	p.nextID++
+	p.batches[id] = &batchState{strategy: strategy, total: total}
+	p.mu.Unlock()
+
+	go p.run(strategy, id)
+	return id
+}
+
+// Results streams the outcome of every task, across every batch
+// submitted to this pool, in completion order.
+func (p *WorkerPool) Results() <-chan TaskResult {
+	return p.results
+}
+
+// BatchStatus reports how many of a batch's tasks have completed
+// successfully and whether that's all of them. This must not touch
+// strategy directly: strategy is only ever safe to drive from the single
+// goroutine running run(), and done/total (updated atomically from
+// there) already tell us everything BatchStatus needs.
+func (p *WorkerPool) BatchStatus(id BatchID) (done, total int, finished bool) {
+	p.mu.Lock()
+	b, ok := p.batches[id]
+	p.mu.Unlock()
+	if !ok {
+		return 0, 0, true
+	}
+	done = int(atomic.LoadInt32(&b.done))
+	return done, b.total, done >= b.total
+}
+
+// run pulls free workers from mr.registerChannel and asks strategy what
+// to do with each one, until the strategy reports every task done and
+// every in-flight attempt has reported back.
+func (p *WorkerPool) run(strategy Strategy, id BatchID) {
+	completed := make(chan TaskResult)
+	outstanding := 0
+	var idle []string // free workers on hand with nothing assigned yet
+
+	handleResult := func(result TaskResult) {
+		outstanding--
+		strategy.Process([]TaskResult{result})
+		if result.OK {
+			idle = append(idle, result.Worker)
+			p.mr.heartbeats.MarkDone(result.Worker)
+			p.markDone(id)
+		}
+		p.results <- result
+	}
+
+	for !strategy.Done() || outstanding > 0 {
+		if len(idle) == 0 {
+			// Nothing on hand yet: listen for either a newly free worker
+			// or a task finishing.
+			select {
+			case worker := <-p.mr.registerChannel:
+				// registerChannel only means the worker is back in
+				// rotation, not that we just heard from it — Register is
+				// called once, by Master.Register, when the worker
+				// actually calls in. Re-registering it here would
+				// resurrect a worker whose heartbeats have been failing,
+				// defeating IsAlive below.
+				if !p.mr.heartbeats.IsAlive(worker) {
+					// Worker went quiet; drop it instead of handing it a
+					// task or putting it back in rotation.
+					debug("Skipping stale worker: %v\n", worker)
+					continue
+				}
+				idle = append(idle, worker)
+			case result := <-completed:
+				handleResult(result)
+			}
+		} else {
+			// Already holding idle workers with nothing to assign them:
+			// pulling more off registerChannel would just pile up more of
+			// the same. Wait for a completion to either free up new work
+			// (Process re-queuing a timed-out task) or let the batch
+			// finish, instead of re-offering what we're already holding
+			// and spinning a fresh goroutine per offer.
+			handleResult(<-completed)
+		}
+
+		if !strategy.Done() && len(idle) > 0 {
+			actions := strategy.NextActions(idle)
+			if len(actions) > 0 {
+				// Counted, not a plain set: idle can hold the same worker
+				// address more than once (e.g. a worker process running
+				// several dispatch slots), and NextActions may only use
+				// some of those slots, so each assignment should remove
+				// exactly one matching entry from idle.
+				assignedCount := make(map[string]int, len(actions))
+				for _, assignment := range actions {
+					assignedCount[assignment.Worker]++
+					outstanding++
+					p.mr.heartbeats.MarkBusy(assignment.Worker, assignment.Task.TaskNumber)
+					go p.runOne(assignment, completed)
+				}
+				remaining := idle[:0]
+				for _, worker := range idle {
+					if assignedCount[worker] > 0 {
+						assignedCount[worker]--
+						continue
+					}
+					remaining = append(remaining, worker)
+				}
+				idle = remaining
+			}
+		}
+	}
+
+	// Anything still idle when the batch finishes goes back into
+	// rotation for whoever submits the next one.
+	for _, worker := range idle {
+		worker := worker
+		go func() { p.mr.registerChannel <- worker }()
+	}
+}
+
+func (p *WorkerPool) markDone(id BatchID) {
+	p.mu.Lock()
+	if b, ok := p.batches[id]; ok {
+		atomic.AddInt32(&b.done, 1)
+	}
+	p.mu.Unlock()
+}
+
+// runOne runs a single task assignment, bounding the RPC by taskTimeout.
+// If the worker hasn't answered in time, it reports failure so the
+// strategy re-assigns the task, but keeps watching in the background: a
+// slow worker that does eventually finish still returns its worker id to
+// the pool, it just can't be counted as the task's completion since the
+// strategy has already moved on.
+func (p *WorkerPool) runOne(assignment TaskAssignment, completed chan<- TaskResult) {
+	var args DoTaskArgs
+	args.JobName = p.mr.jobName
+	args.File = p.mr.files[assignment.Task.TaskNumber]
+	args.Phase = assignment.Task.Phase
+	args.TaskNumber = assignment.Task.TaskNumber
+	args.NumOtherPhase = assignment.Task.NOtherPhase
+
+	done := make(chan bool, 1)
+	go func() { done <- call(assignment.Worker, "Worker.DoTask", args, new(struct{})) }()
+
+	reported := new(int32)
+	select {
+	case ok := <-done:
+		atomic.StoreInt32(reported, 1)
+		completed <- TaskResult{Worker: assignment.Worker, Task: assignment.Task, OK: ok}
+	case <-time.After(taskTimeout):
+		debug("Worker %v timed out on task: %v\n", assignment.Worker, assignment.Task.TaskNumber)
+		completed <- TaskResult{Worker: assignment.Worker, Task: assignment.Task, OK: false}
+		go func() {
+			if <-done && atomic.CompareAndSwapInt32(reported, 0, 1) {
+				p.mr.registerChannel <- assignment.Worker
+			}
+		}()
+	}
+}
@@ -0,0 +1,82 @@
+package mapreduce
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// instantWorker always succeeds immediately, for tests that only care
+// about WorkerPool/Strategy bookkeeping rather than timeout behavior.
+type instantWorker struct{}
+
+func (instantWorker) DoTask(args *DoTaskArgs, _ *struct{}) error { return nil }
+
+// TestWorkerPoolRunsBatchInIsolation exercises the Strategy/WorkerPool
+// subsystem on its own, without going through schedule, confirming the
+// pool is non-blocking (SubmitBatch returns immediately) and that
+// BatchStatus can be polled concurrently with Results() while the batch
+// is in flight, without racing with the run() goroutine driving strategy.
+func TestWorkerPoolRunsBatchInIsolation(t *testing.T) {
+	const ntasks = 10
+	files := make([]string, ntasks)
+	for i := range files {
+		files[i] = "f"
+	}
+	mr := newMaster("", "test-job", files, 1)
+	addr := startTestWorker(t, instantWorker{})
+
+	const nworkers = 4
+	stop := make(chan struct{})
+	defer close(stop)
+	for i := 0; i < nworkers; i++ {
+		go func() {
+			for {
+				select {
+				case mr.registerChannel <- addr:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	pool := NewWorkerPool(mr)
+	tasks := make([]Task, ntasks)
+	for i := range tasks {
+		tasks[i] = Task{TaskNumber: i}
+	}
+	batch := pool.SubmitBatch(NewFanOutStrategy(tasks), len(tasks))
+
+	// SubmitBatch must not block the caller on the batch finishing.
+	if done, total, finished := pool.BatchStatus(batch); finished && done >= total {
+		t.Fatalf("SubmitBatch appears to have run synchronously: done=%d total=%d finished=%v", done, total, finished)
+	}
+
+	// Poll BatchStatus concurrently with draining Results(); run() with
+	// -race would flag this if BatchStatus ever touched strategy directly.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			pool.BatchStatus(batch)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	received := 0
+	for received < len(tasks) {
+		select {
+		case <-pool.Results():
+			received++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only received %d/%d results before timing out", received, len(tasks))
+		}
+	}
+	wg.Wait()
+
+	if done, total, finished := pool.BatchStatus(batch); !finished || done != total {
+		t.Fatalf("BatchStatus after drain = done=%d total=%d finished=%v, want done=total=%d finished=true", done, total, finished, len(tasks))
+	}
+}
@@ -0,0 +1,48 @@
+package chandy_lamport
+
+// TokenMessage represents the transfer of tokens from one server to
+// another over a Link.
+type TokenMessage struct {
+	numTokens int
+}
+
+// MarkerMessage represents the progress of the chandy-lamport protocol:
+// receiving one tells a server to record its state (if it hasn't
+// already) and that the sender has passed through snapshotId's cut.
+type MarkerMessage struct {
+	snapshotId int
+}
+
+// SnapshotMessage is an in-flight application message (e.g. a
+// TokenMessage) recorded as part of a channel's state during a snapshot.
+type SnapshotMessage struct {
+	src     string
+	dest    string
+	message interface{}
+}
+
+// SnapshotState is one server's view of a completed snapshot: its own
+// token count and the messages it recorded on its inbound channels
+// between receiving the first and last marker for snapshotId.
+type SnapshotState struct {
+	id       int
+	tokens   map[string]int
+	messages []*SnapshotMessage
+}
+
+// SendMessageEvent records message being sent from src to dest, to be
+// delivered at receiveTime.
+type SendMessageEvent struct {
+	src         string
+	dest        string
+	message     interface{}
+	receiveTime int
+}
+
+// SentMessageEvent is logged (via the Simulator's logger) whenever a
+// server sends a message, independent of when it's delivered.
+type SentMessageEvent struct {
+	src     string
+	dest    string
+	message interface{}
+}
@@ -0,0 +1,53 @@
+package chandy_lamport
+
+import "sync"
+
+// Queue is a FIFO event queue, safe for concurrent use, backing each
+// Link's in-flight events.
+type Queue struct {
+	mu    sync.Mutex
+	items []interface{}
+}
+
+// NewQueue returns an empty Queue.
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// Push appends item to the back of the queue.
+func (q *Queue) Push(item interface{}) {
+	q.mu.Lock()
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+}
+
+// Pop removes and returns the item at the front of the queue, or
+// (nil, false) if it's empty.
+func (q *Queue) Pop() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item, true
+}
+
+// Peek returns the item at the front of the queue without removing it,
+// or (nil, false) if it's empty.
+func (q *Queue) Peek() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	return q.items[0], true
+}
+
+// Empty reports whether the queue has no items.
+func (q *Queue) Empty() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items) == 0
+}
@@ -17,6 +17,17 @@ type Server struct {
 	outboundLinks map[string]*Link // key = link.dest
 	inboundLinks  map[string]*Link // key = link.src
 	snapshots 	  *SyncMap // A safe Mapping mechanism that contains a mapping from servers Ids and a SnapshotState
+	imports       *SyncMap // Mapping from snapshotId to the *importBuffer being assembled from incoming SnapshotChunkMessages
+	store         SnapshotStore // Where completed snapshots are persisted, if SetSnapshotStore was called; nil means don't persist
+}
+
+// importBuffer accumulates the chunks of a snapshot being imported from a
+// neighbor until every chunk has arrived and it can be handed to
+// ImportSnapshot.
+type importBuffer struct {
+	hash   string
+	total  int
+	chunks [][]byte
 }
 
 type Snapshot struct {
@@ -40,9 +51,17 @@ func NewServer(id string, tokens int, sim *Simulator) *Server {
 		make(map[string]*Link),
 		make(map[string]*Link),
 		NewSyncMap(),
+		NewSyncMap(),
+		nil,
 	}
 }
 
+// SetSnapshotStore configures where this server persists a snapshot once
+// every marker for it has arrived. Pass nil (the default) to not persist.
+func (server *Server) SetSnapshotStore(store SnapshotStore) {
+	server.store = store
+}
+
 // Add a unidirectional link to the destination server
 func (server *Server) AddOutboundLink(dest *Server) {
 	if server == dest {
@@ -133,11 +152,84 @@ func (server *Server) HandlePacket(src string, message interface{}) {
 
 			snapshot.receivedMarkers[src] = true
 			if len(snapshot.receivedMarkers) == len(server.inboundLinks) {
+				if server.store != nil {
+					if err := server.store.Save(snapshotID, &snapshot.state); err != nil {
+						log.Printf("Server %v failed to persist snapshot %v: %v\n", server.Id, snapshotID, err)
+					}
+				}
 				server.sim.NotifySnapshotComplete(server.Id, message.snapshotId)
 			}
+
+		// Case for a neighbor asking whether we hold a finished snapshot.
+		case SnapshotOfferMessage:
+			fmt.Println(server.Id, ": Receives SnapshotOfferMessage from", src)
+			server.sendSnapshotTo(src, message.snapshotId)
+
+		// Case for one chunk of a snapshot being streamed to us.
+		case SnapshotChunkMessage:
+			fmt.Println(server.Id, ": Receives SnapshotChunkMessage from", src)
+			server.receiveSnapshotChunk(message)
+
+		// Case for a neighbor (or the Simulator, on that neighbor's
+		// timeout) giving up on a snapshot that will never complete.
+		case MarkerCancelMessage:
+			fmt.Println(server.Id, ": Receives MarkerCancelMessage from", src)
+			server.snapshots.Delete(message.snapshotId)
+	}
+}
+
+// RequestSnapshot asks every neighbor whether it holds a finished copy of
+// snapshotId, so a freshly-added server can sync to a consistent cut
+// instead of replaying the whole simulation history.
+func (server *Server) RequestSnapshot(snapshotId int) {
+	server.SendToNeighbors(SnapshotOfferMessage{snapshotId})
+}
+
+// sendSnapshotTo exports snapshotId (if this server has it) and streams
+// it to dest as a sequence of SnapshotChunkMessages over the normal link.
+func (server *Server) sendSnapshotTo(dest string, snapshotId int) {
+	chunks, hash, err := server.ExportSnapshot(snapshotId)
+	if err != nil {
+		// We don't have a finished copy of this snapshot; the request
+		// went to every neighbor at once, so another one may answer.
+		return
+	}
+
+	link, ok := server.outboundLinks[dest]
+	if !ok {
+		log.Fatalf("Unknown dest ID %v from server %v\n", dest, server.Id)
+	}
+	for i, chunk := range chunks {
+		message := SnapshotChunkMessage{snapshotId, hash, i, len(chunks), chunk}
+		server.sim.logger.RecordEvent(server, SentMessageEvent{server.Id, dest, message})
+		link.events.Push(SendMessageEvent{server.Id, dest, message, server.sim.GetReceiveTime()})
 	}
 }
 
+// receiveSnapshotChunk buffers an incoming chunk and, once every chunk of
+// that snapshot has arrived, verifies its hash and applies it via
+// ImportSnapshot.
+func (server *Server) receiveSnapshotChunk(message SnapshotChunkMessage) {
+	value, _ := server.imports.LoadOrStore(message.snapshotId, &importBuffer{
+		hash:   message.hash,
+		total:  message.total,
+		chunks: make([][]byte, message.total),
+	})
+	buf := value.(*importBuffer)
+	buf.chunks[message.index] = message.data
+
+	for _, c := range buf.chunks {
+		if c == nil {
+			return // still waiting on more chunks
+		}
+	}
+
+	if err := server.ImportSnapshot(buf.chunks, buf.hash); err != nil {
+		log.Fatalf("Server %v failed to import snapshot %v: %v\n", server.Id, message.snapshotId, err)
+	}
+	server.imports.Delete(message.snapshotId)
+}
+
 // Start the chandy-lamport snapshot algorithm on this server.
 // This should be called only once per server.
 func (server *Server) StartSnapshot(snapshotId int) {
@@ -155,6 +247,11 @@ func (server *Server) StartSnapshot(snapshotId int) {
 	snapshot := Snapshot{snapshotState, make(map[string]bool)}
 	server.snapshots.Store(snapshotId, &snapshot)
 
+	// Guard against a slow or crashed participant leaving this snapshot
+	// permanently incomplete: the Simulator cancels it automatically if
+	// it doesn't finish within its timeout.
+	server.sim.ScheduleSnapshotTimeout(snapshotId, server.Id)
+
 	// Send a marker message to all server outbound links, to take their snapshots.
 	server.SendToNeighbors(MarkerMessage{snapshotId})
 }
@@ -0,0 +1,149 @@
+package chandy_lamport
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultSnapshotTimeout bounds how long a snapshot is allowed to stay
+// incomplete before the Simulator gives up on it and cancels it, so a
+// slow or crashed initiator doesn't leave every participant holding
+// partial state forever.
+const defaultSnapshotTimeout = 5 * time.Second
+
+// EventLogger records every SentMessageEvent (and similar) a server
+// produces, for debugging and tests.
+type EventLogger struct {
+	mu     sync.Mutex
+	events []loggedEvent
+}
+
+type loggedEvent struct {
+	serverId string
+	event    interface{}
+}
+
+// RecordEvent appends event, attributed to server, to the log.
+func (l *EventLogger) RecordEvent(server *Server, event interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, loggedEvent{server.Id, event})
+}
+
+// Events returns a copy of everything recorded so far.
+func (l *EventLogger) Events() []interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]interface{}, len(l.events))
+	for i, e := range l.events {
+		out[i] = e.event
+	}
+	return out
+}
+
+// Simulator drives the chandy-lamport protocol across a set of servers:
+// it assigns message receive times, logs events, notifies initiators when
+// a snapshot completes, and enforces the per-snapshot timeout that
+// auto-cancels a snapshot nobody ever finishes.
+type Simulator struct {
+	mu              sync.Mutex
+	servers         map[string]*Server
+	logger          *EventLogger
+	clock           int
+	snapshotTimeout time.Duration
+	snapshotTimers  map[snapshotTimerKey]*time.Timer
+}
+
+// snapshotTimerKey identifies one participant's auto-cancel timer for one
+// snapshot. Every participant (not just the original initiator) runs
+// StartSnapshot and schedules its own timer for the same snapshotId, so
+// keying by snapshotId alone would let one participant's timer silently
+// replace another's instead of coexisting with it.
+type snapshotTimerKey struct {
+	snapshotId int
+	serverId   string
+}
+
+// NewSimulator returns a Simulator with no servers yet and the default
+// per-snapshot timeout.
+func NewSimulator() *Simulator {
+	return &Simulator{
+		servers:         make(map[string]*Server),
+		logger:          &EventLogger{},
+		snapshotTimeout: defaultSnapshotTimeout,
+		snapshotTimers:  make(map[snapshotTimerKey]*time.Timer),
+	}
+}
+
+// AddServer creates a new server with the given id and initial tokens,
+// registers it with the simulator, and returns it.
+func (sim *Simulator) AddServer(id string, tokens int) *Server {
+	server := NewServer(id, tokens, sim)
+	sim.mu.Lock()
+	sim.servers[id] = server
+	sim.mu.Unlock()
+	return server
+}
+
+// GetReceiveTime returns a monotonically increasing logical time to
+// attach to a newly-sent message, so events can be ordered deterministically.
+func (sim *Simulator) GetReceiveTime() int {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.clock++
+	return sim.clock
+}
+
+// ScheduleSnapshotTimeout starts participant's auto-cancel timer for
+// snapshotId. Every server that starts tracking snapshotId — not just
+// whoever initiated it — calls this for itself, so the timer is keyed by
+// (snapshotId, participant) rather than snapshotId alone: otherwise a
+// downstream participant's call would silently stop and replace the
+// initiator's own timer instead of coexisting with it. If
+// NotifySnapshotComplete for the same (snapshotId, participant) doesn't
+// arrive within sim.snapshotTimeout, participant's own CancelSnapshot is
+// called on the simulator's behalf.
+func (sim *Simulator) ScheduleSnapshotTimeout(snapshotId int, participant string) {
+	key := snapshotTimerKey{snapshotId, participant}
+
+	timer := time.AfterFunc(sim.snapshotTimeout, func() {
+		sim.mu.Lock()
+		delete(sim.snapshotTimers, key)
+		server, ok := sim.servers[participant]
+		sim.mu.Unlock()
+		if ok {
+			server.CancelSnapshot(snapshotId)
+		}
+	})
+
+	sim.mu.Lock()
+	if existing, ok := sim.snapshotTimers[key]; ok {
+		existing.Stop()
+	}
+	sim.snapshotTimers[key] = timer
+	sim.mu.Unlock()
+}
+
+// cancelSnapshotTimeout stops and forgets participant's auto-cancel timer
+// for snapshotId, e.g. because it completed or was cancelled some other
+// way.
+func (sim *Simulator) cancelSnapshotTimeout(snapshotId int, participant string) {
+	key := snapshotTimerKey{snapshotId, participant}
+
+	sim.mu.Lock()
+	timer, ok := sim.snapshotTimers[key]
+	delete(sim.snapshotTimers, key)
+	sim.mu.Unlock()
+	if ok {
+		timer.Stop()
+	}
+}
+
+// NotifySnapshotComplete is called by a server once it's received every
+// marker it was expecting for snapshotId. It stops that server's own
+// auto-cancel timer, since it no longer needs to fire.
+func (sim *Simulator) NotifySnapshotComplete(serverId string, snapshotId int) {
+	sim.cancelSnapshotTimeout(snapshotId, serverId)
+	fmt.Println(serverId, ": snapshot", snapshotId, "complete")
+}
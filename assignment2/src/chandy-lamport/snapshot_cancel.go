@@ -0,0 +1,37 @@
+package chandy_lamport
+
+// MarkerCancelMessage tells every peer participating in snapshotId to
+// discard whatever partial state they've recorded for it and stop
+// recording channel state, because the sending server (or the Simulator,
+// on that server's own timeout) has given up on the snapshot ever
+// completing.
+type MarkerCancelMessage struct {
+	snapshotId int
+}
+
+// CancelSnapshot aborts snapshotId by broadcasting a MarkerCancelMessage
+// so every peer can garbage-collect its partial Snapshot entry and stop
+// recording channel state for it. This is typically called by the
+// Simulator after a per-snapshot timeout, but can also be called directly
+// by whoever initiated the snapshot, in which case the not-yet-fired
+// timeout for it is stopped too.
+func (server *Server) CancelSnapshot(snapshotId int) {
+	server.snapshots.Delete(snapshotId)
+	server.sim.cancelSnapshotTimeout(snapshotId, server.Id)
+	server.SendToNeighbors(MarkerCancelMessage{snapshotId})
+}
+
+// SnapshotProgress reports how many of the markers snapshotId needs (one
+// per inbound link) have been received so far, and whether it's done. If
+// the server has no record of snapshotId at all — it was never started,
+// already completed and forgotten, or was cancelled — it reports done.
+func (server *Server) SnapshotProgress(snapshotId int) (received, expected int, done bool) {
+	value, ok := server.snapshots.Load(snapshotId)
+	if !ok {
+		return 0, 0, true
+	}
+	snapshot := value.(*Snapshot)
+	received = len(snapshot.receivedMarkers)
+	expected = len(server.inboundLinks)
+	return received, expected, received >= expected
+}
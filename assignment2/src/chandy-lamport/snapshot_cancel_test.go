@@ -0,0 +1,113 @@
+package chandy_lamport
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCompletedMultiParticipantSnapshotSurvivesTimeout reproduces a real
+// multi-server snapshot: the initiator's marker makes the other
+// participant call StartSnapshot for itself too (see HandlePacket's
+// MarkerMessage case), so both ends schedule their own auto-cancel
+// timer for the same snapshotId. Both complete well within the timeout,
+// but the timers were previously keyed by snapshotId alone, so the
+// participant's ScheduleSnapshotTimeout call silently replaced the
+// initiator's timer in the map without stopping it — leaving the
+// initiator's original timer to fire later anyway and cancel an
+// already-completed snapshot out from under everyone.
+func TestCompletedMultiParticipantSnapshotSurvivesTimeout(t *testing.T) {
+	sim := NewSimulator()
+	sim.snapshotTimeout = 30 * time.Millisecond
+	a := sim.AddServer("A", 1)
+	b := sim.AddServer("B", 1)
+	a.AddOutboundLink(b)
+	b.AddOutboundLink(a)
+
+	a.StartSnapshot(1)
+	// Deliver A's marker to B: since B has no record of snapshot 1 yet,
+	// HandlePacket has B call StartSnapshot for itself here, scheduling
+	// B's own auto-cancel timer alongside A's.
+	b.HandlePacket("A", MarkerMessage{1})
+	// B's only inbound link is from A, so the marker above already
+	// completed B. Deliver B's marker back to A to complete A too.
+	a.HandlePacket("B", MarkerMessage{1})
+
+	if _, _, done := a.SnapshotProgress(1); !done {
+		t.Fatal("A's snapshot did not complete")
+	}
+	if _, _, done := b.SnapshotProgress(1); !done {
+		t.Fatal("B's snapshot did not complete")
+	}
+
+	// Long enough for either server's now-stale timer to have fired if
+	// it was never actually stopped.
+	time.Sleep(3 * sim.snapshotTimeout)
+
+	if _, ok := a.snapshots.Load(1); !ok {
+		t.Error("A's completed snapshot was cancelled by a stale timer")
+	}
+	if _, ok := b.snapshots.Load(1); !ok {
+		t.Error("B's completed snapshot was cancelled by a stale timer")
+	}
+}
+
+// TestOverlappingSnapshotsCompleteOrTimeOut drives dozens of concurrently
+// initiated snapshots across the same pair of servers: half are
+// delivered their one required marker and complete normally, half never
+// are and must be auto-cancelled once the Simulator's per-snapshot
+// timeout fires, without interfering with each other.
+func TestOverlappingSnapshotsCompleteOrTimeOut(t *testing.T) {
+	sim := NewSimulator()
+	sim.snapshotTimeout = 30 * time.Millisecond
+	a := sim.AddServer("A", 1)
+	b := sim.AddServer("B", 1)
+	a.AddOutboundLink(b)
+	b.AddOutboundLink(a)
+
+	const n = 40
+	for id := 0; id < n; id++ {
+		a.StartSnapshot(id)
+		if id%2 == 0 {
+			// Complete this one right away by delivering A's one
+			// required marker (A has a single inbound link, from B).
+			a.HandlePacket("B", MarkerMessage{id})
+		}
+		// The odd ids are left hanging on purpose: B never gets asked to
+		// deliver a marker back, so A's snapshot for that id can only
+		// ever be resolved by the timeout.
+	}
+
+	for id := 0; id < n; id++ {
+		if id%2 == 0 {
+			received, expected, done := a.SnapshotProgress(id)
+			if !done || received < expected {
+				t.Errorf("snapshot %d: received=%d expected=%d done=%v, want completed", id, received, expected, done)
+			}
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		allCancelled := true
+		for id := 1; id < n; id += 2 {
+			if _, ok := a.snapshots.Load(id); ok {
+				allCancelled = false
+				break
+			}
+		}
+		if allCancelled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not every hung snapshot was auto-cancelled before the deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Completed snapshots must survive the other ones' timeouts firing.
+	for id := 0; id < n; id += 2 {
+		if _, ok := a.snapshots.Load(id); !ok {
+			t.Errorf("snapshot %d: completed snapshot was removed by an unrelated timeout", id)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package chandy_lamport
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotStore persists completed snapshots so they outlive the
+// simulation run that produced them, giving users a durable artifact for
+// cross-run debugging or for syncing a new server via ExportSnapshot /
+// ImportSnapshot.
+type SnapshotStore interface {
+	Save(snapshotId int, state *SnapshotState) error
+	Load(snapshotId int) (*SnapshotState, error)
+	List() ([]int, error)
+}
+
+// FileSnapshotStore is a SnapshotStore backed by one gob-encoded file per
+// snapshot under dir.
+type FileSnapshotStore struct {
+	dir string
+}
+
+// NewFileSnapshotStore returns a FileSnapshotStore rooted at dir, creating
+// the directory if it doesn't already exist.
+func NewFileSnapshotStore(dir string) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSnapshotStore{dir: dir}, nil
+}
+
+func (s *FileSnapshotStore) path(snapshotId int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("snapshot-%d.gob", snapshotId))
+}
+
+// Save gob-encodes state (via the same exported mirror ExportSnapshot
+// uses, since SnapshotState's own fields aren't exported) and merges it
+// into this snapshot's file.
+//
+// Every server that completes snapshotId calls Save with its own
+// single-server SnapshotState (see StartSnapshot: tokens only ever has
+// one entry, its own Id). Overwriting the file on each call would leave
+// only the last server's contribution, so this loads whatever's already
+// there first and merges the new server's tokens entry and messages in,
+// building up the full multi-server cut one Save at a time.
+func (s *FileSnapshotStore) Save(snapshotId int, state *SnapshotState) error {
+	exported, err := s.loadExported(snapshotId)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		exported = &exportedSnapshot{Tokens: make(map[string]int)}
+	}
+
+	for id, tokens := range state.tokens {
+		exported.Tokens[id] = tokens
+	}
+	for _, m := range state.messages {
+		exported.Messages = append(exported.Messages, exportedMessage{m.src, m.dest, m.message})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(*exported); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(snapshotId), buf.Bytes(), 0644)
+}
+
+func (s *FileSnapshotStore) loadExported(snapshotId int) (*exportedSnapshot, error) {
+	data, err := os.ReadFile(s.path(snapshotId))
+	if err != nil {
+		return nil, err
+	}
+
+	var exported exportedSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&exported); err != nil {
+		return nil, err
+	}
+	return &exported, nil
+}
+
+func (s *FileSnapshotStore) Load(snapshotId int) (*SnapshotState, error) {
+	exported, err := s.loadExported(snapshotId)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*SnapshotMessage, 0, len(exported.Messages))
+	for _, m := range exported.Messages {
+		msg := SnapshotMessage{m.Src, m.Dest, m.Message}
+		messages = append(messages, &msg)
+	}
+	state := SnapshotState{snapshotId, exported.Tokens, messages}
+	return &state, nil
+}
+
+func (s *FileSnapshotStore) List() ([]int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, entry := range entries {
+		var id int
+		if _, err := fmt.Sscanf(entry.Name(), "snapshot-%d.gob", &id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// RecoverFromStore rebuilds every server's Tokens and in-flight messages
+// from the persisted snapshot snapshotId, re-injecting each recorded
+// message onto the appropriate Link.events queue in FIFO order. This is
+// the same consistent-cut state ImportSnapshot applies to a single
+// server, just driven from durable storage instead of a live neighbor.
+func (sim *Simulator) RecoverFromStore(store SnapshotStore, snapshotId int) error {
+	state, err := store.Load(snapshotId)
+	if err != nil {
+		return err
+	}
+
+	for id, tokens := range state.tokens {
+		if server, ok := sim.servers[id]; ok {
+			server.Tokens = tokens
+		}
+	}
+
+	for _, server := range sim.servers {
+		for _, link := range server.inboundLinks {
+			link.events = NewQueue()
+		}
+	}
+
+	for _, m := range state.messages {
+		dest, ok := sim.servers[m.dest]
+		if !ok {
+			continue
+		}
+		link, ok := dest.inboundLinks[m.src]
+		if !ok {
+			continue
+		}
+		link.events.Push(SendMessageEvent{m.src, m.dest, m.message, sim.GetReceiveTime()})
+	}
+	return nil
+}
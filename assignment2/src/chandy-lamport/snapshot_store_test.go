@@ -0,0 +1,55 @@
+package chandy_lamport
+
+import "testing"
+
+// TestFileSnapshotStoreMergesPerServerContributions exercises a snapshot
+// across three servers sharing a FileSnapshotStore: each server's Save
+// call only ever carries its own Tokens entry, so the store must merge
+// them rather than overwrite, and RecoverFromStore must then restore
+// every server's Tokens, not just the last one saved.
+func TestFileSnapshotStoreMergesPerServerContributions(t *testing.T) {
+	store, err := NewFileSnapshotStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileSnapshotStore: %v", err)
+	}
+
+	sim := NewSimulator()
+	a := sim.AddServer("A", 5)
+	b := sim.AddServer("B", 2)
+	c := sim.AddServer("C", 0)
+	for _, x := range []*Server{a, b, c} {
+		x.SetSnapshotStore(store)
+	}
+	// A ring: A->B->C->A, so each server has exactly one inbound link and
+	// its own snapshot completes (and persists) as soon as that one
+	// marker arrives.
+	a.AddOutboundLink(b)
+	b.AddOutboundLink(c)
+	c.AddOutboundLink(a)
+
+	a.StartSnapshot(42)
+	b.HandlePacket("A", MarkerMessage{42})
+	c.HandlePacket("B", MarkerMessage{42})
+	a.HandlePacket("C", MarkerMessage{42})
+
+	state, err := store.Load(42)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]int{"A": 5, "B": 2, "C": 0}
+	for id, tokens := range want {
+		if got, ok := state.tokens[id]; !ok || got != tokens {
+			t.Errorf("state.tokens[%q] = %d, ok=%v, want %d", id, got, ok, tokens)
+		}
+	}
+
+	// Mutate every server's live Tokens so RecoverFromStore's effect is
+	// observable, then recover and check each one is restored.
+	a.Tokens, b.Tokens, c.Tokens = 999, 999, 999
+	if err := sim.RecoverFromStore(store, 42); err != nil {
+		t.Fatalf("RecoverFromStore: %v", err)
+	}
+	if a.Tokens != 5 || b.Tokens != 2 || c.Tokens != 0 {
+		t.Fatalf("after RecoverFromStore: A=%d B=%d C=%d, want A=5 B=2 C=0", a.Tokens, b.Tokens, c.Tokens)
+	}
+}
@@ -0,0 +1,165 @@
+package chandy_lamport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// snapshotChunkSize bounds how many bytes of a serialized snapshot are
+// carried in a single SnapshotChunkMessage, so a large snapshot doesn't
+// need to fit in one packet.
+const snapshotChunkSize = 4096
+
+// init registers every message type that can end up recorded inside a
+// SnapshotState's messages (and therefore inside an exportedSnapshot) so
+// gob can encode/decode them through the interface{} they're boxed in.
+// Without this, Export/Import and the SnapshotStore both fail at runtime
+// with "gob: type not registered".
+func init() {
+	gob.Register(TokenMessage{})
+	gob.Register(MarkerMessage{})
+}
+
+// GobEncode and GobDecode carry TokenMessage's unexported numTokens field,
+// which gob would otherwise refuse to encode ("has no exported fields").
+func (m TokenMessage) GobEncode() ([]byte, error) {
+	return []byte(strconv.Itoa(m.numTokens)), nil
+}
+
+func (m *TokenMessage) GobDecode(data []byte) error {
+	n, err := strconv.Atoi(string(data))
+	m.numTokens = n
+	return err
+}
+
+// SnapshotOfferMessage asks a neighbor whether it holds a finished copy
+// of snapshotId. A server that has it responds with a SnapshotChunkMessage
+// per chunk; a server that doesn't just ignores the offer.
+type SnapshotOfferMessage struct {
+	snapshotId int
+}
+
+// SnapshotChunkMessage carries one chunk of a serialized SnapshotState.
+// hash is the sha256 of the complete serialized snapshot, repeated on
+// every chunk so the receiver can verify the reassembled payload without
+// a separate round trip. index/total let chunks be reassembled in order.
+type SnapshotChunkMessage struct {
+	snapshotId int
+	hash       string
+	index      int
+	total      int
+	data       []byte
+}
+
+// exportedMessage and exportedSnapshot mirror the unexported
+// SnapshotMessage/SnapshotState fields in an encodable (exported) shape,
+// used only to serialize a completed snapshot for transfer to another
+// server.
+type exportedMessage struct {
+	Src     string
+	Dest    string
+	Message interface{}
+}
+
+type exportedSnapshot struct {
+	OwnerId  string // Id of the server this snapshot was recorded by/for
+	Tokens   map[string]int
+	Messages []exportedMessage
+}
+
+// ExportSnapshot serializes the already-completed snapshot snapshotId
+// into fixed-size chunks (each suitable for a SnapshotChunkMessage) along
+// with the sha256 hash of the full serialized payload.
+//
+// A server's own SnapshotState only ever records its own Tokens and the
+// messages it saw on its own inbound channels (see StartSnapshot) — it's
+// not a full cut across every server in the simulation. So what this
+// exports, and what ImportSnapshot below restores, is this server's own
+// previously-recorded state, not anyone else's.
+func (server *Server) ExportSnapshot(snapshotId int) (chunks [][]byte, hash string, err error) {
+	value, ok := server.snapshots.Load(snapshotId)
+	if !ok {
+		return nil, "", fmt.Errorf("server %v has no snapshot %v", server.Id, snapshotId)
+	}
+	snapshot := value.(*Snapshot)
+	if len(snapshot.receivedMarkers) < len(server.inboundLinks) {
+		return nil, "", fmt.Errorf("server %v has not completed snapshot %v", server.Id, snapshotId)
+	}
+
+	exported := exportedSnapshot{OwnerId: server.Id, Tokens: snapshot.state.tokens}
+	for _, m := range snapshot.state.messages {
+		exported.Messages = append(exported.Messages, exportedMessage{m.src, m.dest, m.message})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(exported); err != nil {
+		return nil, "", err
+	}
+
+	data := buf.Bytes()
+	sum := sha256.Sum256(data)
+	for len(data) > 0 {
+		n := snapshotChunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks, hex.EncodeToString(sum[:]), nil
+}
+
+// ImportSnapshot reassembles chunks produced by ExportSnapshot, verifies
+// them against hash, and atomically replaces this server's Tokens with
+// the recorded state: any pending inbound events are dropped first, and
+// the recorded in-flight messages are replayed onto the appropriate
+// Link.events queues in FIFO order, so the server jumps directly to that
+// consistent cut instead of replaying the whole simulation history.
+//
+// Only a server recovering its own prior state can use this: the chunks
+// must have come from an ExportSnapshot call made by a server with the
+// same Id as this one (e.g. a cached copy a neighbor kept across this
+// server's restart). A different server's export is refused with an
+// error, since that server's SnapshotState never holds an entry for any
+// Id but its own.
+func (server *Server) ImportSnapshot(chunks [][]byte, hash string) error {
+	var buf bytes.Buffer
+	for _, c := range chunks {
+		buf.Write(c)
+	}
+	data := buf.Bytes()
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != hash {
+		return fmt.Errorf("server %v: snapshot hash mismatch, refusing to import", server.Id)
+	}
+
+	var exported exportedSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&exported); err != nil {
+		return err
+	}
+	if exported.OwnerId != server.Id {
+		return fmt.Errorf("server %v: refusing to import a snapshot recorded by %v", server.Id, exported.OwnerId)
+	}
+
+	for _, link := range server.inboundLinks {
+		// link is shared with the sender's outboundLinks entry, so
+		// replacing its queue in place (rather than swapping in a new
+		// *Link) keeps both sides pointing at the same drained queue.
+		link.events = NewQueue()
+	}
+
+	server.Tokens = exported.Tokens[server.Id]
+	for _, m := range exported.Messages {
+		link, ok := server.inboundLinks[m.Src]
+		if !ok {
+			continue
+		}
+		link.events.Push(SendMessageEvent{m.Src, m.Dest, m.Message, server.sim.GetReceiveTime()})
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package chandy_lamport
+
+import "testing"
+
+// TestImportSnapshotRecoversOwnTokens simulates a server being killed and
+// replaced: it exports its own completed snapshot before "crashing", and
+// a fresh instance with the same Id imports that export and ends up with
+// the same token count.
+func TestImportSnapshotRecoversOwnTokens(t *testing.T) {
+	sim := NewSimulator()
+	a := sim.AddServer("A", 5)
+	b := sim.AddServer("B", 0)
+	a.AddOutboundLink(b)
+	b.AddOutboundLink(a)
+
+	a.StartSnapshot(1)
+	// B has exactly one inbound link (from A), so delivering A's marker
+	// completes B's half of the snapshot.
+	b.HandlePacket("A", MarkerMessage{1})
+	// A has exactly one inbound link (from B), so delivering B's marker
+	// completes A's half.
+	a.HandlePacket("B", MarkerMessage{1})
+
+	chunks, hash, err := a.ExportSnapshot(1)
+	if err != nil {
+		t.Fatalf("ExportSnapshot: %v", err)
+	}
+
+	// Simulate A crashing and being replaced by a fresh instance with the
+	// same Id, recovering from a neighbor's cached copy of its export.
+	newA := NewServer("A", 0, sim)
+	if err := newA.ImportSnapshot(chunks, hash); err != nil {
+		t.Fatalf("ImportSnapshot: %v", err)
+	}
+	if newA.Tokens != 5 {
+		t.Fatalf("newA.Tokens = %d, want 5", newA.Tokens)
+	}
+}
+
+// TestImportSnapshotRejectsOtherServersExport ensures a server can't
+// mistakenly import a neighbor's own export: that export's Tokens map
+// only ever has the neighbor's Id as a key, so applying it here would
+// silently zero this server's Tokens.
+func TestImportSnapshotRejectsOtherServersExport(t *testing.T) {
+	sim := NewSimulator()
+	a := sim.AddServer("A", 5)
+	b := sim.AddServer("B", 0)
+	a.AddOutboundLink(b)
+	b.AddOutboundLink(a)
+
+	a.StartSnapshot(1)
+	b.HandlePacket("A", MarkerMessage{1})
+	a.HandlePacket("B", MarkerMessage{1})
+
+	chunks, hash, err := a.ExportSnapshot(1)
+	if err != nil {
+		t.Fatalf("ExportSnapshot: %v", err)
+	}
+
+	if err := b.ImportSnapshot(chunks, hash); err == nil {
+		t.Fatal("ImportSnapshot: expected an error importing A's export into B, got nil")
+	}
+}
+
+// TestImportSnapshotRejectsBadHash ensures a corrupted/truncated transfer
+// is rejected rather than silently applied.
+func TestImportSnapshotRejectsBadHash(t *testing.T) {
+	sim := NewSimulator()
+	a := sim.AddServer("A", 5)
+	b := sim.AddServer("B", 0)
+	a.AddOutboundLink(b)
+	b.AddOutboundLink(a)
+
+	a.StartSnapshot(1)
+	b.HandlePacket("A", MarkerMessage{1})
+	a.HandlePacket("B", MarkerMessage{1})
+
+	chunks, _, err := a.ExportSnapshot(1)
+	if err != nil {
+		t.Fatalf("ExportSnapshot: %v", err)
+	}
+
+	if err := a.ImportSnapshot(chunks, "not-the-real-hash"); err == nil {
+		t.Fatal("ImportSnapshot: expected a hash-mismatch error, got nil")
+	}
+}
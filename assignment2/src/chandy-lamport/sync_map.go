@@ -0,0 +1,15 @@
+package chandy_lamport
+
+import "sync"
+
+// SyncMap is a thin, typed-in-name-only wrapper around sync.Map, used
+// wherever a Server needs a map that's safe to read and write from both
+// the simulator's delivery goroutine and HandlePacket.
+type SyncMap struct {
+	sync.Map
+}
+
+// NewSyncMap returns an empty SyncMap.
+func NewSyncMap() *SyncMap {
+	return &SyncMap{}
+}
@@ -0,0 +1,15 @@
+package chandy_lamport
+
+import "sort"
+
+// getSortedKeys returns a link map's destination/source server Ids in
+// sorted order, so broadcasts like SendToNeighbors have a deterministic
+// iteration order.
+func getSortedKeys(links map[string]*Link) []string {
+	keys := make([]string, 0, len(links))
+	for k := range links {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}